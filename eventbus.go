@@ -0,0 +1,19 @@
+package cache
+
+// EventBus lets multiple processes sharing the same MongoCache
+// invalidate each other's in-memory layers (useful once a two-tier
+// cache exists, e.g. LoadingMongoCache). A write publishes the key;
+// every other process's Subscribe callback can then drop that key from
+// any local layer it keeps.
+type EventBus interface {
+	Publish(key string) error
+	Subscribe(fn func(key string)) error
+}
+
+// noopEventBus is the default EventBus: every publish is dropped and
+// Subscribe never calls back. It lets MongoCache publish unconditionally
+// without a nil check on every write.
+type noopEventBus struct{}
+
+func (noopEventBus) Publish(key string) error            { return nil }
+func (noopEventBus) Subscribe(fn func(key string)) error { return nil }
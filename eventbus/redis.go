@@ -0,0 +1,71 @@
+// Package eventbus provides a Redis-backed cache.EventBus, using
+// PUBLISH/SUBSCRIBE on a configurable channel to invalidate a
+// MongoCache's local layers across processes.
+package eventbus
+
+import (
+	"context"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+const defaultChannel = "cache:invalidate"
+
+// RedisEventBus publishes and subscribes to key invalidations over a
+// single redis pub/sub channel shared by every process using the same
+// MongoCache collection.
+type RedisEventBus struct {
+	client  *goredis.Client
+	channel string
+}
+
+// New wraps an existing, already-connected redis client. Pass
+// WithChannel to use something other than the default channel name.
+func New(client *goredis.Client, opts ...func(*RedisEventBus)) *RedisEventBus {
+	b := &RedisEventBus{
+		client:  client,
+		channel: defaultChannel,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// WithChannel overrides the default pub/sub channel name.
+func WithChannel(channel string) func(*RedisEventBus) {
+	return func(b *RedisEventBus) {
+		b.channel = channel
+	}
+}
+
+// Publish announces that key changed to every subscriber on the bus's
+// channel.
+func (b *RedisEventBus) Publish(key string) error {
+	return b.client.Publish(context.Background(), b.channel, key).Err()
+}
+
+// Subscribe calls fn with the key from every message received on the
+// bus's channel, for as long as the subscription's underlying
+// connection stays open. It starts a goroutine that runs for the life
+// of the process: there is no Unsubscribe/Close, so the subscription
+// (and the goroutine reading it) is only ever torn down if the redis
+// client itself is closed, at which point sub.Channel() closes and the
+// goroutine returns. Callers needing a bounded subscription lifetime
+// should close the client when they're done.
+func (b *RedisEventBus) Subscribe(fn func(key string)) error {
+	sub := b.client.Subscribe(context.Background(), b.channel)
+	if _, err := sub.Receive(context.Background()); err != nil {
+		return err
+	}
+
+	go func() {
+		for msg := range sub.Channel() {
+			fn(msg.Payload)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,137 @@
+//go:build !nomgo
+// +build !nomgo
+
+package cache
+
+import (
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+)
+
+// TypedCache is the generic counterpart of Cache. Go does not allow a
+// generic type to share a name with an existing non-generic one, so this
+// is not literally "Cache[T]", but it plays the same role: callers who
+// know their concrete value type get Get/Set back typed as T instead of
+// interface{}, without the runtime type assertions the interface{}-based
+// Cache requires.
+type TypedCache[T any] interface {
+	Get(key string) (T, error)
+	Set(key string, value T) error
+	SetEx(key string, duration time.Duration, value T) error
+	Delete(key string) error
+	StartGCol(gcInterval time.Duration)
+	StopGCol()
+}
+
+// KeyValueT is the generic counterpart of KeyValue: Value is stored as a
+// concrete T rather than interface{}, so inserts never need a type
+// assertion on the way in. TenantID is only populated when the owning
+// MongoCache has one set, so deleteExpiredTenantKeys's {tenant_id,
+// expire_at} sweep also reaps expired typed entries instead of leaving
+// them to expire lazily on Get.
+type KeyValueT[T any] struct {
+	Key       string    `bson:"_id"`
+	TenantID  string    `bson:"tenant_id,omitempty"`
+	Value     T         `bson:"value"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpireAt  time.Time `bson:"expire_at"`
+}
+
+// DocumentT is the generic counterpart of Document, returned when
+// reading a typed entry back out of mongo.
+type DocumentT[T any] struct {
+	Key      string    `bson:"_id"`
+	Value    T         `bson:"value"`
+	ExpireAt time.Time `bson:"expire_at"`
+}
+
+// MongoCacheT wraps a *MongoCache and narrows the mongo documents it
+// reads and writes to a concrete value type T. It shares the session,
+// collection name, TTL and GC bookkeeping of the embedded *MongoCache;
+// only the marshaling boundary differs, so StartGCol/StopGCol are
+// inherited unchanged.
+type MongoCacheT[T any] struct {
+	*MongoCache
+}
+
+// NewTypedMongoCache creates a typed caching layer backed by mongo. It
+// accepts the same configuration funcs as NewMongoCacheWithTTL since
+// TTL, collection name and GC behaviour don't depend on the value type.
+//
+// e.g. (usage):
+// NewTypedMongoCache[User](session)
+//
+// configure ttl duration with;
+// NewTypedMongoCache[User](session, func(m *MongoCache) {
+// m.TTL = 2 * time.Minute
+// })
+func NewTypedMongoCache[T any](session *mgo.Session, configs ...func(*MongoCache)) TypedCache[T] {
+	mc := NewMongoCacheWithTTL(session, configs...).(*MongoCache)
+	return &MongoCacheT[T]{MongoCache: mc}
+}
+
+// Get returns the value of a given key if it exists and has not expired.
+func (m *MongoCacheT[T]) Get(key string) (T, error) {
+	var zero T
+
+	var doc DocumentT[T]
+	query := func(c *mgo.Collection) error {
+		return c.FindId(m.docID(key)).One(&doc)
+	}
+
+	if err := m.run(m.CollectionName, query); err != nil {
+		if err == mgo.ErrNotFound {
+			return zero, ErrNotFound
+		}
+		return zero, err
+	}
+
+	if m.now().UTC().After(doc.ExpireAt) {
+		_ = m.DeleteKey(m.docID(key))
+		return zero, ErrNotFound
+	}
+
+	return doc.Value, nil
+}
+
+// Set will persist a value to the cache or override the existing one
+// with the new one, using the cache's configured TTL.
+func (m *MongoCacheT[T]) Set(key string, value T) error {
+	return m.SetEx(key, m.TTL, value)
+}
+
+// SetEx persists a value to the cache with a custom expiration duration.
+func (m *MongoCacheT[T]) SetEx(key string, duration time.Duration, value T) error {
+	if m.UseServerTTL {
+		if err := m.ensureTTLIndex(); err != nil {
+			return err
+		}
+	}
+
+	kv := &KeyValueT[T]{
+		Key:       m.docID(key),
+		TenantID:  m.TenantID,
+		Value:     value,
+		CreatedAt: m.now().UTC(),
+		ExpireAt:  m.now().UTC().Add(duration),
+	}
+
+	upsert := func(c *mgo.Collection) error {
+		_, err := c.UpsertId(kv.Key, kv)
+		return err
+	}
+
+	if err := m.run(m.CollectionName, upsert); err != nil {
+		return err
+	}
+	return m.eventBus.Publish(key)
+}
+
+// Delete deletes a given key if it exists.
+func (m *MongoCacheT[T]) Delete(key string) error {
+	if err := m.DeleteKey(m.docID(key)); err != nil {
+		return err
+	}
+	return m.eventBus.Publish(key)
+}
@@ -0,0 +1,113 @@
+//go:build !nomgo
+// +build !nomgo
+
+package cache
+
+import (
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// LoadingMongoCache wraps a *MongoCache with a loader function that
+// regenerates a value on a miss or an expired entry. Concurrent callers
+// asking for the same key during a miss share a single loader call via
+// an internal singleflight.Group, so a hot key under load triggers the
+// loader once instead of once per caller (the cache-stampede problem a
+// bare Get/Set pair doesn't solve).
+type LoadingMongoCache struct {
+	*MongoCache
+
+	loader func(key string) (interface{}, error)
+
+	// RefreshAhead, when non-zero, makes Get return a cached value
+	// immediately once it is within RefreshAhead of expiring, while
+	// kicking off an asynchronous loader call to refresh it before it
+	// actually expires.
+	RefreshAhead time.Duration
+
+	sf singleflight.Group
+}
+
+// NewLoadingMongoCache creates a loading caching layer backed by mongo.
+// loader is called to regenerate a value whenever Get misses or finds
+// an expired entry. It accepts the same configuration funcs style as
+// NewMongoCacheWithTTL plus loading-specific ones such as
+// SetRefreshAhead.
+//
+// e.g. (usage):
+// NewLoadingMongoCache(session, loadUser)
+//
+// configure refresh-ahead with;
+// NewLoadingMongoCache(session, loadUser, SetRefreshAhead(10*time.Second))
+func NewLoadingMongoCache(session *mgo.Session, loader func(key string) (interface{}, error), configs ...func(*LoadingMongoCache)) *LoadingMongoCache {
+	lc := &LoadingMongoCache{
+		MongoCache: NewMongoCacheWithTTL(session).(*MongoCache),
+		loader:     loader,
+	}
+
+	for _, configFunc := range configs {
+		configFunc(lc)
+	}
+
+	if lc.StartGC && !lc.UseServerTTL {
+		lc.StartGCol(lc.GCInterval)
+	}
+
+	return lc
+}
+
+// SetRefreshAhead configures how long before expiry a Get should return
+// the cached value while refreshing it in the background.
+func SetRefreshAhead(d time.Duration) func(*LoadingMongoCache) {
+	return func(l *LoadingMongoCache) {
+		l.RefreshAhead = d
+	}
+}
+
+// Get returns the value of a given key, regenerating it via loader if
+// it is missing or expired. Concurrent misses for the same key are
+// collapsed into a single loader call.
+func (l *LoadingMongoCache) Get(key string) (interface{}, error) {
+	var doc Document
+	query := func(c *mgo.Collection) error {
+		return c.FindId(l.docID(key)).One(&doc)
+	}
+
+	switch err := l.run(l.CollectionName, query); {
+	case err == mgo.ErrNotFound:
+		return l.reload(key)
+	case err != nil:
+		return nil, err
+	}
+
+	if l.now().UTC().After(doc.ExpireAt) {
+		return l.reload(key)
+	}
+
+	if l.RefreshAhead > 0 && doc.ExpireAt.Sub(l.now()) < l.RefreshAhead {
+		go func() {
+			_, _ = l.reload(key)
+		}()
+	}
+
+	return doc.Value, nil
+}
+
+// reload regenerates key via loader and stores the result, collapsing
+// concurrent calls for the same key into one loader invocation.
+func (l *LoadingMongoCache) reload(key string) (interface{}, error) {
+	value, err, _ := l.sf.Do(key, func() (interface{}, error) {
+		value, err := l.loader(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.Set(key, value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+
+	return value, err
+}
@@ -0,0 +1,180 @@
+// Package memory is an in-process, map-based Cache adapter. It is
+// registered under the name "memory" so it can be built through
+// cache.NewCache("memory", cfg) alongside the mongo and redis backends.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mehmetalisavas/cache"
+)
+
+const defaultExpireDuration = time.Minute
+
+func init() {
+	cache.Register("memory", func(cfg string) (cache.Cache, error) {
+		return New(), nil
+	})
+}
+
+// Cache is an in-process Cache backed by a plain map. It exists for
+// services that want the shared Cache interface without a network
+// round trip, e.g. in tests or single-instance deployments.
+type Cache struct {
+	// TTL is a duration for a cache key to expire
+	TTL time.Duration
+
+	GCInterval time.Duration
+
+	// StartGC starts the garbage collector and deletes the expired
+	// keys with given time interval
+	StartGC bool
+
+	gcTicker *time.Ticker
+	done     chan struct{}
+
+	sync.RWMutex
+	items map[string]entry
+}
+
+type entry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+// SetTTL overrides the default expiration duration for keys written
+// without SetEx.
+func SetTTL(d time.Duration) func(*Cache) {
+	return func(c *Cache) {
+		c.TTL = d
+	}
+}
+
+// SetGCInterval overrides the default garbage collector interval.
+func SetGCInterval(d time.Duration) func(*Cache) {
+	return func(c *Cache) {
+		c.GCInterval = d
+	}
+}
+
+// StartGC starts the garbage collector on construction.
+func StartGC() func(*Cache) {
+	return func(c *Cache) {
+		c.StartGC = true
+	}
+}
+
+// New creates an in-process cache. cfg passed through cache.NewCache is
+// ignored since there is nothing to connect to.
+//
+// configure ttl duration with;
+// New(SetTTL(2 * time.Minute))
+func New(configs ...func(*Cache)) *Cache {
+	c := &Cache{
+		TTL:        defaultExpireDuration,
+		GCInterval: time.Minute,
+		items:      make(map[string]entry),
+	}
+
+	for _, configFunc := range configs {
+		configFunc(c)
+	}
+
+	if c.StartGC {
+		c.StartGCol(c.GCInterval)
+	}
+
+	return c
+}
+
+// Get returns a value of a given key if it exists and has not expired.
+func (c *Cache) Get(key string) (interface{}, error) {
+	c.RLock()
+	e, ok := c.items[key]
+	c.RUnlock()
+
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	if time.Now().UTC().After(e.expireAt) {
+		c.Delete(key)
+		return nil, cache.ErrNotFound
+	}
+
+	return e.value, nil
+}
+
+// Set will persist a value to the cache or override the existing one
+// with the new one, using the cache's configured TTL.
+func (c *Cache) Set(key string, value interface{}) error {
+	return c.SetEx(key, c.TTL, value)
+}
+
+// SetEx persists a value to the cache with a custom expiration duration.
+func (c *Cache) SetEx(key string, duration time.Duration, value interface{}) error {
+	c.Lock()
+	c.items[key] = entry{value: value, expireAt: time.Now().UTC().Add(duration)}
+	c.Unlock()
+	return nil
+}
+
+// Delete deletes a given key if it exists.
+func (c *Cache) Delete(key string) error {
+	c.Lock()
+	delete(c.items, key)
+	c.Unlock()
+	return nil
+}
+
+// StartGCol starts the garbage collector with given time interval
+func (c *Cache) StartGCol(gcInterval time.Duration) {
+	if gcInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(gcInterval)
+	done := make(chan struct{})
+
+	c.Lock()
+	c.gcTicker = ticker
+	c.done = done
+	c.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				c.deleteExpired()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// StopGCol stops the sweeping goroutine.
+func (c *Cache) StopGCol() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.gcTicker != nil {
+		c.gcTicker.Stop()
+		c.gcTicker = nil
+		close(c.done)
+		c.done = nil
+	}
+}
+
+func (c *Cache) deleteExpired() {
+	now := time.Now().UTC()
+
+	c.Lock()
+	defer c.Unlock()
+
+	for key, e := range c.items {
+		if now.After(e.expireAt) {
+			delete(c.items, key)
+		}
+	}
+}
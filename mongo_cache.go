@@ -1,3 +1,11 @@
+//go:build !nomgo
+// +build !nomgo
+
+// This file implements MongoCache on top of the unmaintained
+// gopkg.in/mgo.v2 driver. It is kept for one release cycle so callers
+// can migrate to cache/mongov2 (go.mongodb.org/mongo-driver) at their
+// own pace; build with -tags nomgo to drop the mgo dependency entirely
+// once migrated.
 package cache
 
 import (
@@ -5,6 +13,7 @@ import (
 	"time"
 
 	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
 // MongoCache holds the cache values that will be stored in mongoDB
@@ -22,21 +31,54 @@ type MongoCache struct {
 	// expired keys from mongo with given time interval
 	StartGC bool
 
+	// UseServerTTL switches expiry to a MongoDB TTL index on ExpireAt
+	// instead of the in-process sweeper. Only enable this when the
+	// collection is dedicated to this cache, or when every writer
+	// shares (and accepts) the same minimum TTL, since a TTL index
+	// applies one expireAfterSeconds value to the whole collection.
+	UseServerTTL bool
+
+	// TenantID, when set, namespaces every key under this tenant so a
+	// single collection can safely back many tenants. See SetTenant
+	// and WithTenant.
+	TenantID string
+
+	// eventBus is notified of every successful write so other
+	// processes sharing this collection can invalidate their own
+	// local layers. Defaults to a no-op bus; see SetEventBus.
+	eventBus EventBus
+
 	// gcTicker controls gc intervals
 	gcTicker *time.Ticker
 
 	// done controls sweeping goroutine lifetime
 	done chan struct{}
 
+	// ttlIndexOnce ensures the server TTL index is only ensured once
+	ttlIndexOnce sync.Once
+	ttlIndexErr  error
+
+	// tenantIndexOnce ensures the tenant-scoped indexes are only
+	// ensured once
+	tenantIndexOnce sync.Once
+	tenantIndexErr  error
+
+	// now returns the current time. It defaults to time.Now and is
+	// only ever overridden in tests, via SetClock, so expiry logic can
+	// be driven deterministically instead of with time.Sleep.
+	now func() time.Time
+
 	// Mutex is used for handling the concurrent
 	// read/write requests for cache
 	sync.RWMutex
 }
 
-// NewMongoCacheWithTTL creates a caching layer backed by mongo. TTL's are
-// managed either by a background cleaner or document is removed on the Get
-// operation. Mongo TTL indexes are not utilized since there can be multiple
-// systems using the same collection with different TTL values.
+// NewMongoCacheWithTTL creates a caching layer backed by mongo. By default,
+// TTL's are managed either by a background cleaner or document is removed
+// on the Get operation, and no Mongo TTL index is created since there can
+// be multiple systems using the same collection with different TTL values.
+// Pass UseServerTTL() to opt into a native Mongo TTL index instead (see its
+// doc comment for the tradeoffs).
 //
 // The responsibility of stopping the GC process belongs to the user.
 //
@@ -62,19 +104,67 @@ func NewMongoCacheWithTTL(session *mgo.Session, configs ...func(*MongoCache)) Ca
 		CollectionName: defaultKeyValueColl,
 		GCInterval:     time.Minute,
 		StartGC:        false,
+		eventBus:       noopEventBus{},
+		now:            time.Now,
 	}
 
 	for _, configFunc := range configs {
 		configFunc(mc)
 	}
 
-	if mc.StartGC {
+	if mc.StartGC && !mc.UseServerTTL {
 		mc.StartGCol(mc.GCInterval)
 	}
 
 	return mc
 }
 
+// UseServerTTL is an option func for NewMongoCacheWithTTL that switches
+// expiry from the in-process sweeper to a native MongoDB TTL index on
+// ExpireAt, with expireAfterSeconds: 0 (documents expire exactly at
+// ExpireAt). The index is created, or replaced if a mismatched TTL index
+// already exists under the same key, on the first Set. StartGCol is not
+// started in this mode. Since a TTL index applies to the whole
+// collection, callers sharing a collection across writers with different
+// TTLs should use a dedicated collection per TTL instead.
+func UseServerTTL() func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.UseServerTTL = true
+	}
+}
+
+// SetClock overrides the function MongoCache uses to read the current
+// time. It exists for tests that need deterministic TTL/GC behaviour
+// without sleeping past the real expiry duration.
+func SetClock(now func() time.Time) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.now = now
+	}
+}
+
+// SetEventBus wires bus into a MongoCache so Set, SetEx and Delete
+// publish the written key after the mongo write succeeds.
+func SetEventBus(bus EventBus) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.eventBus = bus
+	}
+}
+
+// docID returns the document _id a plain (non-tenant-table) document
+// for key should use: key itself for an un-namespaced cache, or the
+// collision-safe tenantDocID encoding when m.TenantID is set. It backs
+// the typed cache, which reads and writes _id directly rather than
+// through the TenantDocument table that Get/Set/Delete/StartGCol use on
+// *MongoCache itself, and stamps its own tenant_id field (see KeyValueT)
+// so deleteExpiredTenantKeys's sweep still reaps its expired entries. The
+// loading cache uses it only for reads; its writes go through Set.
+func (m *MongoCache) docID(key string) string {
+	if m.TenantID == "" {
+		return key
+	}
+	return tenantDocID(m.TenantID, key)
+}
+
 // WithStartGC adds the given value to the WithStartGC
 // this is an external way to change WithStartGC value as true
 // recommended way is : add together with NewMongoCacheWithTTL()
@@ -83,33 +173,186 @@ func (m *MongoCache) WithStartGC(isStart bool) *MongoCache {
 	return m
 }
 
-// Get returns a value of a given key if it exists
+// Get returns a value of a given key if it exists. When m.TenantID is
+// set, the lookup is scoped to that tenant via getTenant instead of the
+// plain, unnamespaced document. The expiry check is driven by m.now(),
+// not time.Now, so SetClock lets tests observe expiry deterministically.
 func (m *MongoCache) Get(key string) (interface{}, error) {
-	return m.GetKeyWithExpireCheck(key)
+	if m.TenantID != "" {
+		return m.getTenant(m.TenantID, key)
+	}
+
+	var doc Document
+	query := func(c *mgo.Collection) error {
+		return c.FindId(key).One(&doc)
+	}
+
+	if err := m.run(m.CollectionName, query); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if m.now().UTC().After(doc.ExpireAt) {
+		_ = m.DeleteKey(key)
+		return nil, ErrNotFound
+	}
+
+	return doc.Value, nil
 }
 
 // Set will persist a value to the cache or
 // override existing one with the new one
 func (m *MongoCache) Set(key string, value interface{}) error {
-	return m.set(key, value)
+	if m.TenantID != "" {
+		if err := m.setTenant(m.TenantID, key, m.TTL, value); err != nil {
+			return err
+		}
+		return m.eventBus.Publish(key)
+	}
+
+	if err := m.set(key, value); err != nil {
+		return err
+	}
+	return m.eventBus.Publish(key)
+}
+
+// SetEx persists a value to the cache with a custom expiration
+// duration, overriding the cache's configured TTL for this key.
+func (m *MongoCache) SetEx(key string, duration time.Duration, value interface{}) error {
+	if m.TenantID != "" {
+		if err := m.setTenant(m.TenantID, key, duration, value); err != nil {
+			return err
+		}
+		return m.eventBus.Publish(key)
+	}
+
+	if m.UseServerTTL {
+		if err := m.ensureTTLIndex(); err != nil {
+			return err
+		}
+	}
+
+	kv := &KeyValue{
+		Key:       key,
+		Value:     value,
+		CreatedAt: m.now().UTC(),
+		ExpireAt:  m.now().UTC().Add(duration),
+	}
+
+	if err := m.CreateKeyValueWithExpiration(kv); err != nil {
+		return err
+	}
+	return m.eventBus.Publish(key)
 }
 
 // Delete deletes a given key if exists
 func (m *MongoCache) Delete(key string) error {
-	return m.DeleteKey(key)
+	if m.TenantID != "" {
+		if err := m.deleteTenant(m.TenantID, key); err != nil {
+			return err
+		}
+		return m.eventBus.Publish(key)
+	}
+
+	if err := m.DeleteKey(key); err != nil {
+		return err
+	}
+	return m.eventBus.Publish(key)
 }
 
 func (m *MongoCache) set(key string, value interface{}) error {
+	if m.UseServerTTL {
+		if err := m.ensureTTLIndex(); err != nil {
+			return err
+		}
+	}
+
 	kv := &KeyValue{
 		Key:       key,
 		Value:     value,
-		CreatedAt: time.Now().UTC(),
-		ExpireAt:  time.Now().UTC().Add(m.TTL),
+		CreatedAt: m.now().UTC(),
+		ExpireAt:  m.now().UTC().Add(m.TTL),
 	}
 
 	return m.CreateKeyValueWithExpiration(kv)
 }
 
+// ttlIndexName is the name of the server TTL index ensureTTLIndex
+// manages on expire_at.
+const ttlIndexName = "expire_at_ttl"
+
+// ensureTTLIndex creates the expire_at TTL index the first time it is
+// called, replacing any existing index on the same key that isn't
+// already this exact TTL index.
+//
+// mgo.Collection.EnsureIndex can't express expireAfterSeconds: 0 since
+// mgo only emits that field when Index.ExpireAfter > 0, so documents
+// would expire exactly at ExpireAt but EnsureIndex would silently build
+// a plain, non-expiring index instead. The index is created via a raw
+// createIndexes command to get expireAfterSeconds: 0 onto the wire.
+func (m *MongoCache) ensureTTLIndex() error {
+	m.ttlIndexOnce.Do(func() {
+		createTTLIndex := func(c *mgo.Collection) error {
+			return c.Database.Run(bson.D{
+				{Name: "createIndexes", Value: c.Name},
+				{Name: "indexes", Value: []bson.M{
+					{
+						"key":                bson.M{"expire_at": 1},
+						"name":               ttlIndexName,
+						"expireAfterSeconds": 0,
+						"background":         true,
+					},
+				}},
+			}, nil)
+		}
+
+		query := func(c *mgo.Collection) error {
+			existing, err := c.Indexes()
+			if err != nil {
+				return err
+			}
+
+			// Any existing index on the same key that isn't already
+			// ours needs to go first, whether it's a plain non-TTL
+			// index (ExpireAfter == 0, indistinguishable at the mgo
+			// level from a TTL index whose expireAfterSeconds is
+			// itself 0) or a TTL index with a different interval -
+			// createIndexes errors with an options conflict if left
+			// in place, since it reuses the same key pattern.
+			for _, idx := range existing {
+				if len(idx.Key) == 1 && idx.Key[0] == "expire_at" && idx.Name != ttlIndexName {
+					if err := c.DropIndexName(idx.Name); err != nil {
+						return err
+					}
+					break
+				}
+			}
+
+			return createTTLIndex(c)
+		}
+
+		m.ttlIndexErr = m.run(m.CollectionName, query)
+	})
+
+	return m.ttlIndexErr
+}
+
+// deleteExpiredKeys removes every document in the collection whose
+// ExpireAt is at or before m.now(), driving the GC sweep off the
+// injected clock rather than time.Now so SetClock is honored in tests.
+func (m *MongoCache) deleteExpiredKeys() error {
+	sweep := func(c *mgo.Collection) error {
+		_, err := c.RemoveAll(bson.M{
+			"expire_at": bson.M{"$lte": m.now().UTC()},
+		})
+		return err
+	}
+
+	return m.run(m.CollectionName, sweep)
+}
+
 // StartGCol starts the garbage collector with given time interval
 func (m *MongoCache) StartGCol(gcInterval time.Duration) {
 	if gcInterval <= 0 {
@@ -129,7 +372,11 @@ func (m *MongoCache) StartGCol(gcInterval time.Duration) {
 			select {
 			case <-ticker.C:
 				m.Lock()
-				m.DeleteExpiredKeys()
+				if m.TenantID != "" {
+					m.deleteExpiredTenantKeys(m.TenantID)
+				} else {
+					m.deleteExpiredKeys()
+				}
 				m.Unlock()
 			case <-done:
 				return
@@ -138,14 +385,27 @@ func (m *MongoCache) StartGCol(gcInterval time.Duration) {
 	}()
 }
 
-// StopGC stops sweeping goroutine.
-func (r *MemoryTTL) StopGCol() {
-	if r.gcTicker != nil {
-		r.Lock()
-		r.gcTicker.Stop()
-		r.gcTicker = nil
-		close(r.done)
-		r.done = nil
-		r.Unlock()
+// StopGCol stops the sweeping goroutine.
+func (m *MongoCache) StopGCol() {
+	if m.gcTicker != nil {
+		m.Lock()
+		m.gcTicker.Stop()
+		m.gcTicker = nil
+		close(m.done)
+		m.done = nil
+		m.Unlock()
 	}
 }
+
+// init registers MongoCache under the "mongo" name so it can be built
+// through NewCache alongside the memory and redis adapters. cfg is
+// treated as the mgo.Dial connection string.
+func init() {
+	Register("mongo", func(cfg string) (Cache, error) {
+		session, err := mgo.Dial(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewMongoCacheWithTTL(session), nil
+	})
+}
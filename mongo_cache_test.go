@@ -2,6 +2,7 @@ package cache
 
 import (
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -184,16 +185,38 @@ func TestMongoCacheDelete(t *testing.T) {
 	}
 }
 
+// fakeClock returns a now func driven by an in-memory time.Time that
+// tests can move forward with advance, so TTL/GC expiry can be exercised
+// deterministically instead of sleeping past the real duration.
+func fakeClock(start time.Time) (now func() time.Time, advance func(time.Duration)) {
+	var mu sync.Mutex
+	current := start
+
+	now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+	advance = func(d time.Duration) {
+		mu.Lock()
+		current = current.Add(d)
+		mu.Unlock()
+	}
+
+	return now, advance
+}
+
 func TestMongoCacheTTL(t *testing.T) {
 	// duration specifies the time duration to hold the data in mongo
 	// after the duration interval, data will be deleted from mongoDB
 	duration := time.Millisecond * 100
+	now, advance := fakeClock(time.Now())
 
-	mgoCache := NewMongoCacheWithTTL(session, SetTTL(duration))
+	mgoCache := NewMongoCacheWithTTL(session, SetTTL(duration), SetClock(now))
 	if mgoCache == nil {
 		t.Fatal("config should not be nil")
 	}
-	defer mgoCache.StopGC()
+	defer mgoCache.StopGCol()
 
 	key, value := bson.NewObjectId().Hex(), bson.NewObjectId().Hex()
 
@@ -207,7 +230,7 @@ func TestMongoCacheTTL(t *testing.T) {
 		t.Fatalf("data should equal: %v, but got: %v", value, data)
 	}
 
-	time.Sleep(duration)
+	advance(duration)
 
 	if _, err := mgoCache.Get(key); err != ErrNotFound {
 		t.Fatalf("error should equal to %q but got: %q", ErrNotFound, err)
@@ -220,13 +243,14 @@ func TestMongoCacheGC(t *testing.T) {
 	// duration specifies the time duration to hold the data in mongo
 	// after the duration interval, data will be deleted from mongoDB
 	duration := time.Millisecond * 100
+	now, advance := fakeClock(time.Now())
 
-	mgoCache := NewMongoCacheWithTTL(session, SetTTL(duration/2), SetGCInterval(duration), StartGC())
+	mgoCache := NewMongoCacheWithTTL(session, SetTTL(duration/2), SetGCInterval(duration), StartGC(), SetClock(now)).(*MongoCache)
 	if mgoCache == nil {
 		t.Fatal("config should not be nil")
 	}
 
-	defer mgoCache.StopGC()
+	defer mgoCache.StopGCol()
 
 	key, value := bson.NewObjectId().Hex(), bson.NewObjectId().Hex()
 	key1, value1 := bson.NewObjectId().Hex(), bson.NewObjectId().Hex()
@@ -250,6 +274,10 @@ func TestMongoCacheGC(t *testing.T) {
 		t.Fatalf("data should equal: %v, but got: %v", value1, data1)
 	}
 
+	advance(duration)
+	// the GC ticker itself still runs on the wall clock, only the
+	// expiry check it drives is clock-controlled, so give it one real
+	// interval to fire.
 	time.Sleep(duration)
 
 	docs, err := getAllDocuments(mgoCache, key1, key1)
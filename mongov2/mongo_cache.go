@@ -0,0 +1,184 @@
+// Package mongov2 is the migration path off the unmaintained
+// gopkg.in/mgo.v2 driver: it implements the same cache surface as the
+// root package's MongoCache, but on top of the official
+// go.mongodb.org/mongo-driver, with a context.Context threaded through
+// every operation so TLS, SRV connection strings, server-side sessions
+// and retryable writes all work the way they do anywhere else in the
+// driver. The mgo-based implementation in the root package is kept
+// behind the nomgo build tag for one release cycle so callers can
+// migrate incrementally.
+package mongov2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultExpireDuration = time.Minute
+
+// ErrNotFound is returned when a key does not exist or has expired.
+var ErrNotFound = mongo.ErrNoDocuments
+
+// Cache is the mongov2 counterpart of the root package's Cache
+// interface, with a context.Context on every operation.
+type Cache interface {
+	Get(ctx context.Context, key string) (interface{}, error)
+	Set(ctx context.Context, key string, value interface{}) error
+	SetEx(ctx context.Context, key string, duration time.Duration, value interface{}) error
+	Delete(ctx context.Context, key string) error
+	StartGCol(gcInterval time.Duration)
+	StopGCol()
+}
+
+// Document is the mongov2 counterpart of the root package's Document.
+type Document struct {
+	Key      string      `bson:"_id"`
+	Value    interface{} `bson:"value"`
+	ExpireAt time.Time   `bson:"expire_at"`
+}
+
+// KeyValue is the mongov2 counterpart of the root package's KeyValue.
+type KeyValue struct {
+	Key       string      `bson:"_id"`
+	Value     interface{} `bson:"value"`
+	CreatedAt time.Time   `bson:"created_at"`
+	ExpireAt  time.Time   `bson:"expire_at"`
+}
+
+// MongoCache holds the cache values that will be stored in mongoDB
+// through a *mongo.Collection from the official driver.
+type MongoCache struct {
+	collection *mongo.Collection
+
+	TTL        time.Duration
+	GCInterval time.Duration
+	StartGC    bool
+
+	gcTicker *time.Ticker
+	done     chan struct{}
+
+	// now returns the current time; overridable in tests via SetClock.
+	now func() time.Time
+
+	sync.RWMutex
+}
+
+// NewMongoCacheWithTTL creates a caching layer backed by mongo through
+// the official driver. It mirrors the root package's
+// NewMongoCacheWithTTL option-func style.
+//
+// e.g. (usage):
+// NewMongoCacheWithTTL(client.Database("app").Collection("cache"))
+//
+// configure ttl duration with;
+// NewMongoCacheWithTTL(collection, func(m *MongoCache) {
+// m.TTL = 2 * time.Minute
+// })
+func NewMongoCacheWithTTL(collection *mongo.Collection, configs ...func(*MongoCache)) Cache {
+	mc := &MongoCache{
+		collection: collection,
+		TTL:        defaultExpireDuration,
+		GCInterval: time.Minute,
+		now:        time.Now,
+	}
+
+	for _, configFunc := range configs {
+		configFunc(mc)
+	}
+
+	if mc.StartGC {
+		mc.StartGCol(mc.GCInterval)
+	}
+
+	return mc
+}
+
+// Get returns a value of a given key if it exists and has not expired.
+func (m *MongoCache) Get(ctx context.Context, key string) (interface{}, error) {
+	var doc Document
+	err := m.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.now().UTC().After(doc.ExpireAt) {
+		_ = m.Delete(ctx, key)
+		return nil, ErrNotFound
+	}
+
+	return doc.Value, nil
+}
+
+// Set will persist a value to the cache or override the existing one
+// with the new one, using the cache's configured TTL.
+func (m *MongoCache) Set(ctx context.Context, key string, value interface{}) error {
+	return m.SetEx(ctx, key, m.TTL, value)
+}
+
+// SetEx persists a value to the cache with a custom expiration
+// duration, overriding the cache's configured TTL for this key.
+func (m *MongoCache) SetEx(ctx context.Context, key string, duration time.Duration, value interface{}) error {
+	kv := KeyValue{
+		Key:       key,
+		Value:     value,
+		CreatedAt: m.now().UTC(),
+		ExpireAt:  m.now().UTC().Add(duration),
+	}
+
+	_, err := m.collection.ReplaceOne(ctx, bson.M{"_id": key}, kv, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete deletes a given key if it exists.
+func (m *MongoCache) Delete(ctx context.Context, key string) error {
+	_, err := m.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+// StartGCol starts the garbage collector with given time interval
+func (m *MongoCache) StartGCol(gcInterval time.Duration) {
+	if gcInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(gcInterval)
+	done := make(chan struct{})
+
+	m.Lock()
+	m.gcTicker = ticker
+	m.done = done
+	m.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), gcInterval)
+				_, _ = m.collection.DeleteMany(ctx, bson.M{"expire_at": bson.M{"$lte": m.now().UTC()}})
+				cancel()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// StopGCol stops the sweeping goroutine.
+func (m *MongoCache) StopGCol() {
+	if m.gcTicker != nil {
+		m.Lock()
+		m.gcTicker.Stop()
+		m.gcTicker = nil
+		close(m.done)
+		m.done = nil
+		m.Unlock()
+	}
+}
@@ -0,0 +1,33 @@
+package mongov2
+
+import "time"
+
+// SetTTL overrides the default expiration duration for keys written
+// without SetEx.
+func SetTTL(d time.Duration) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.TTL = d
+	}
+}
+
+// SetGCInterval overrides the default garbage collector interval.
+func SetGCInterval(d time.Duration) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.GCInterval = d
+	}
+}
+
+// StartGC starts the garbage collector on construction.
+func StartGC() func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.StartGC = true
+	}
+}
+
+// SetClock overrides the function MongoCache uses to read the current
+// time, for deterministic TTL/GC tests.
+func SetClock(now func() time.Time) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.now = now
+	}
+}
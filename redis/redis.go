@@ -0,0 +1,131 @@
+// Package redis is a Redis-backed Cache adapter. It is registered under
+// the name "redis" so it can be built through cache.NewCache("redis",
+// cfg) alongside the mongo and memory backends. cfg is the redis.Options
+// address, e.g. "127.0.0.1:6379".
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"github.com/mehmetalisavas/cache"
+)
+
+const defaultExpireDuration = time.Minute
+
+func init() {
+	cache.Register("redis", func(cfg string) (cache.Cache, error) {
+		return New(goredis.NewClient(&goredis.Options{Addr: cfg})), nil
+	})
+}
+
+// Cache is a Cache backed by a redis server. Values are JSON-encoded so
+// Get round-trips the same shape Set was given instead of flattening
+// everything to a string. GC is a no-op since redis already expires
+// keys on its own; GCInterval/StartGC/StartGCol/StopGCol exist only to
+// satisfy the shared option-func style and the Cache interface.
+type Cache struct {
+	client *goredis.Client
+
+	// TTL is a duration for a cache key to expire
+	TTL time.Duration
+
+	// GCInterval and StartGC are accepted for parity with the other
+	// adapters but have no effect: redis expires keys natively.
+	GCInterval time.Duration
+	StartGC    bool
+}
+
+// SetTTL overrides the default expiration duration for keys written
+// without SetEx.
+func SetTTL(d time.Duration) func(*Cache) {
+	return func(c *Cache) {
+		c.TTL = d
+	}
+}
+
+// SetGCInterval is accepted for parity with the other adapters; redis
+// expires keys natively so it has no effect.
+func SetGCInterval(d time.Duration) func(*Cache) {
+	return func(c *Cache) {
+		c.GCInterval = d
+	}
+}
+
+// StartGC is accepted for parity with the other adapters; redis expires
+// keys natively so it has no effect.
+func StartGC() func(*Cache) {
+	return func(c *Cache) {
+		c.StartGC = true
+	}
+}
+
+// New wraps an existing redis client. client is expected to be already
+// configured and connected.
+//
+// configure ttl duration with;
+// New(client, SetTTL(2 * time.Minute))
+func New(client *goredis.Client, configs ...func(*Cache)) *Cache {
+	c := &Cache{
+		client:     client,
+		TTL:        defaultExpireDuration,
+		GCInterval: time.Minute,
+	}
+
+	for _, configFunc := range configs {
+		configFunc(c)
+	}
+
+	return c
+}
+
+// Get returns a value of a given key if it exists, JSON-decoded back
+// into the same shape it was Set with.
+func (c *Cache) Get(key string) (interface{}, error) {
+	raw, err := c.client.Get(context.Background(), key).Bytes()
+	if err == goredis.Nil {
+		return nil, cache.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Set will persist a value to the cache or override the existing one
+// with the new one, using the cache's configured TTL.
+func (c *Cache) Set(key string, value interface{}) error {
+	return c.SetEx(key, c.TTL, value)
+}
+
+// SetEx persists a value to the cache with a custom expiration duration.
+// value is JSON-encoded before being written.
+func (c *Cache) SetEx(key string, duration time.Duration, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(context.Background(), key, raw, duration).Err()
+}
+
+// Delete deletes a given key if it exists.
+func (c *Cache) Delete(key string) error {
+	return c.client.Del(context.Background(), key).Err()
+}
+
+// StartGCol is a no-op: redis expires keys natively and needs no
+// in-process sweeper.
+func (c *Cache) StartGCol(gcInterval time.Duration) {}
+
+// StopGCol is a no-op, see StartGCol.
+func (c *Cache) StopGCol() {}
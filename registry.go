@@ -0,0 +1,39 @@
+package cache
+
+import "fmt"
+
+// Factory builds a configured Cache from a backend-specific config
+// string (e.g. a DSN), mirroring the driver registries used by
+// database/sql and Beego's cache package.
+type Factory func(cfg string) (Cache, error)
+
+var adapters = make(map[string]Factory)
+
+// Register makes a cache backend available under name for NewCache to
+// look up. Adapters are expected to call Register from an init() func,
+// so importing an adapter package for its side effect is enough to make
+// it available (see the memory and redis subpackages).
+//
+// It panics if Register is called twice with the same name, or with a
+// nil factory.
+func Register(name string, factory Factory) {
+	if factory == nil {
+		panic("cache: Register factory is nil")
+	}
+	if _, dup := adapters[name]; dup {
+		panic("cache: Register called twice for adapter " + name)
+	}
+	adapters[name] = factory
+}
+
+// NewCache builds the named adapter using cfg and returns it behind the
+// shared Cache interface, so a service can swap Mongo for Redis or an
+// in-process cache without touching call sites.
+func NewCache(name, cfg string) (Cache, error) {
+	factory, ok := adapters[name]
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown adapter %q (forgotten import?)", name)
+	}
+
+	return factory(cfg)
+}
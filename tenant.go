@@ -0,0 +1,214 @@
+//go:build !nomgo
+// +build !nomgo
+
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// tenantKeyIndexName and tenantExpireIndexName are the compound indexes
+// ensureTenantIndexes manages once a MongoCache has any tenant-scoped
+// traffic, either via SetTenant or WithTenant.
+const (
+	tenantKeyIndexName    = "tenant_id_key"
+	tenantExpireIndexName = "tenant_id_expire_at"
+)
+
+// TenantDocument is the document shape stored for every tenant-scoped
+// key. Unlike the colon-joined keys an earlier version of this file
+// used, TenantID and Key are stored as their own fields - so lookups and
+// the GC sweep can filter on tenant_id directly instead of parsing it
+// back out of _id - and _id is built by tenantDocID so two different
+// (tenantID, key) pairs can never collide on the same document.
+type TenantDocument struct {
+	ID        string      `bson:"_id"`
+	TenantID  string      `bson:"tenant_id"`
+	Key       string      `bson:"key"`
+	Value     interface{} `bson:"value"`
+	CreatedAt time.Time   `bson:"created_at"`
+	ExpireAt  time.Time   `bson:"expire_at"`
+}
+
+// SetTenant dedicates a MongoCache to a single tenant: every key passed
+// to Get/Set/Delete is namespaced under tenantID, so the collection can
+// safely back many tenants with no cross-tenant leakage. Use this at
+// construction time when a whole cache instance belongs to one tenant;
+// use WithTenant to carve out a scoped view at request time instead.
+func SetTenant(tenantID string) func(*MongoCache) {
+	return func(m *MongoCache) {
+		m.TenantID = tenantID
+	}
+}
+
+// tenantDocID builds the compound _id for a tenant-scoped document.
+// Joining tenantID and key with a plain separator is ambiguous - tenant
+// "a" key "b:c" would collide with tenant "a:b" key "c" - so the
+// encoding is prefixed with the length of tenantID instead, which fixes
+// exactly where tenantID ends regardless of what either part contains.
+func tenantDocID(tenantID, key string) string {
+	return strconv.Itoa(len(tenantID)) + ":" + tenantID + key
+}
+
+// ensureTenantIndexes creates the compound indexes tenant-scoped lookups
+// and sweeps rely on: {tenant_id:1,key:1} (unique, since it backs every
+// point lookup and upsert) and {tenant_id:1,expire_at:1} (for the
+// tenant-filtered GC sweep in deleteExpiredTenantKeys).
+func (m *MongoCache) ensureTenantIndexes() error {
+	m.tenantIndexOnce.Do(func() {
+		create := func(c *mgo.Collection) error {
+			return c.Database.Run(bson.D{
+				{Name: "createIndexes", Value: c.Name},
+				{Name: "indexes", Value: []bson.M{
+					{
+						"key":    bson.D{{Name: "tenant_id", Value: 1}, {Name: "key", Value: 1}},
+						"name":   tenantKeyIndexName,
+						"unique": true,
+					},
+					{
+						"key":  bson.D{{Name: "tenant_id", Value: 1}, {Name: "expire_at", Value: 1}},
+						"name": tenantExpireIndexName,
+					},
+				}},
+			}, nil)
+		}
+
+		m.tenantIndexErr = m.run(m.CollectionName, create)
+	})
+
+	return m.tenantIndexErr
+}
+
+// getTenant returns the value of key scoped to tenantID if it exists and
+// has not expired.
+func (m *MongoCache) getTenant(tenantID, key string) (interface{}, error) {
+	var doc TenantDocument
+	query := func(c *mgo.Collection) error {
+		return c.FindId(tenantDocID(tenantID, key)).One(&doc)
+	}
+
+	if err := m.run(m.CollectionName, query); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if m.now().UTC().After(doc.ExpireAt) {
+		_ = m.deleteTenant(tenantID, key)
+		return nil, ErrNotFound
+	}
+
+	return doc.Value, nil
+}
+
+// setTenant persists value for key scoped to tenantID, expiring after
+// duration.
+func (m *MongoCache) setTenant(tenantID, key string, duration time.Duration, value interface{}) error {
+	if err := m.ensureTenantIndexes(); err != nil {
+		return err
+	}
+
+	if m.UseServerTTL {
+		if err := m.ensureTTLIndex(); err != nil {
+			return err
+		}
+	}
+
+	doc := &TenantDocument{
+		ID:        tenantDocID(tenantID, key),
+		TenantID:  tenantID,
+		Key:       key,
+		Value:     value,
+		CreatedAt: m.now().UTC(),
+		ExpireAt:  m.now().UTC().Add(duration),
+	}
+
+	upsert := func(c *mgo.Collection) error {
+		_, err := c.UpsertId(doc.ID, doc)
+		return err
+	}
+
+	return m.run(m.CollectionName, upsert)
+}
+
+// deleteTenant deletes key scoped to tenantID if it exists.
+func (m *MongoCache) deleteTenant(tenantID, key string) error {
+	remove := func(c *mgo.Collection) error {
+		err := c.RemoveId(tenantDocID(tenantID, key))
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return m.run(m.CollectionName, remove)
+}
+
+// deleteExpiredTenantKeys removes every expired document scoped to
+// tenantID, leaving other tenants' keys in the same collection
+// untouched.
+func (m *MongoCache) deleteExpiredTenantKeys(tenantID string) error {
+	sweep := func(c *mgo.Collection) error {
+		_, err := c.RemoveAll(bson.M{
+			"tenant_id": tenantID,
+			"expire_at": bson.M{"$lte": m.now().UTC()},
+		})
+		return err
+	}
+
+	return m.run(m.CollectionName, sweep)
+}
+
+// tenantCache is a shallow, tenant-scoped view of a *MongoCache: it
+// shares the same session, collection and TTL/GC configuration of the
+// cache it was derived from, only namespacing the keys it reads and
+// writes.
+type tenantCache struct {
+	*MongoCache
+	tenantID string
+}
+
+// WithTenant returns a Cache scoped to tenantID, sharing this
+// MongoCache's session and collection. It is meant for request-scoped
+// use, e.g. deriving a per-tenant cache from one shared *MongoCache
+// without opening a new session per tenant.
+func (m *MongoCache) WithTenant(tenantID string) Cache {
+	return &tenantCache{MongoCache: m, tenantID: tenantID}
+}
+
+// Get returns a value of a given key if it exists, scoped to this
+// tenant.
+func (t *tenantCache) Get(key string) (interface{}, error) {
+	return t.MongoCache.getTenant(t.tenantID, key)
+}
+
+// Set persists a value for this tenant, using the underlying cache's
+// configured TTL.
+func (t *tenantCache) Set(key string, value interface{}) error {
+	if err := t.MongoCache.setTenant(t.tenantID, key, t.MongoCache.TTL, value); err != nil {
+		return err
+	}
+	return t.MongoCache.eventBus.Publish(key)
+}
+
+// SetEx persists a value for this tenant with a custom expiration
+// duration.
+func (t *tenantCache) SetEx(key string, duration time.Duration, value interface{}) error {
+	if err := t.MongoCache.setTenant(t.tenantID, key, duration, value); err != nil {
+		return err
+	}
+	return t.MongoCache.eventBus.Publish(key)
+}
+
+// Delete deletes a given key for this tenant if it exists.
+func (t *tenantCache) Delete(key string) error {
+	if err := t.MongoCache.deleteTenant(t.tenantID, key); err != nil {
+		return err
+	}
+	return t.MongoCache.eventBus.Publish(key)
+}